@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// weatherCache is an on-disk cache of raw upstream responses, keyed by an
+// arbitrary string. It exists to avoid burning upstream API quota when the
+// same location (or the same city/zip lookup) is requested repeatedly (e.g.
+// by a dashboard or e-paper display).
+//
+//   - younger than ttl: the cached body is returned as-is.
+//   - older than ttl but younger than maxAge: the cached body is returned
+//     immediately and a refresh is kicked off in the background.
+//   - older than maxAge, or missing: fetched synchronously.
+type weatherCache struct {
+	dir    string
+	ttl    time.Duration
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+func newWeatherCache(dir string, ttl, maxAge time.Duration) *weatherCache {
+	return &weatherCache{dir: dir, ttl: ttl, maxAge: maxAge, now: time.Now}
+}
+
+// fetch returns the cached body for key if it's fresh enough, otherwise it
+// calls fetchFresh and caches the result. For an async stale-while-revalidate
+// refresh, fetchFresh is invoked with its own upstreamTimeout-bounded
+// context rather than the caller's ctx, so it must not depend on the
+// request's deadline in that case.
+func (c *weatherCache) fetch(ctx context.Context, key string, fetchFresh func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	entry, err := c.read(key)
+	if err != nil {
+		slog.Error("read weather cache", "error", err)
+	}
+
+	if entry != nil {
+		age := c.now().Sub(entry.FetchedAt)
+
+		if age < c.ttl {
+			cacheResultsTotal.WithLabelValues("hit").Inc()
+			return entry.Body, nil
+		}
+
+		if age < c.maxAge {
+			cacheResultsTotal.WithLabelValues("stale_hit").Inc()
+			go func() {
+				refreshCtx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+				defer cancel()
+
+				body, err := fetchFresh(refreshCtx)
+				if err != nil {
+					slog.Error("background refresh", "error", err)
+					return
+				}
+				if err := c.write(key, body); err != nil {
+					slog.Error("write weather cache", "error", err)
+				}
+			}()
+			return entry.Body, nil
+		}
+	}
+
+	cacheResultsTotal.WithLabelValues("miss").Inc()
+
+	body, err := fetchFresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.write(key, body); err != nil {
+		slog.Error("write weather cache", "error", err)
+	}
+
+	return body, nil
+}
+
+func (c *weatherCache) read(key string) (*cacheEntry, error) {
+	raw, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (c *weatherCache) write(key string, body []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(cacheEntry{FetchedAt: c.now(), Body: body})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path(key), raw, 0o644)
+}
+
+func (c *weatherCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// roundCoord rounds a coordinate to two decimal places (~1.1km of
+// resolution at the equator), so that requests for "the same" location with
+// slightly different precision still hit the cache.
+func roundCoord(x float32) float32 {
+	return float32(math.Round(float64(x)*100) / 100)
+}
+
+// coordCacheKey builds the cache key for a coordinate-based upstream fetch
+// (a provider's current-weather lookup, or OWM's forecast) at the given
+// (rounded) coordinates and units.
+func coordCacheKey(provider, units string, lat, lon float32) string {
+	return fmt.Sprintf("%s_%s_%.2f_%.2f", provider, units, roundCoord(lat), roundCoord(lon))
+}
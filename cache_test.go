@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWeatherCacheFreshHitSkipsFetch(t *testing.T) {
+	now := time.Now()
+	c := newWeatherCache(t.TempDir(), 10*time.Minute, time.Hour)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	fetch := func(ctx context.Context) ([]byte, error) {
+		calls++
+		return []byte(`{"n":1}`), nil
+	}
+
+	body, err := c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch)
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if string(body) != `{"n":1}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+
+	c.now = func() time.Time { return now.Add(5 * time.Minute) }
+	body, err = c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if string(body) != `{"n":1}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if calls != 1 {
+		t.Fatalf("expected cache hit to skip fetch, got %d calls", calls)
+	}
+}
+
+func TestWeatherCacheStaleTriggersBackgroundRefresh(t *testing.T) {
+	now := time.Now()
+	c := newWeatherCache(t.TempDir(), 10*time.Minute, time.Hour)
+	c.now = func() time.Time { return now }
+
+	var mu sync.Mutex
+	calls := 0
+	done := make(chan struct{}, 1)
+	fetch := func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		if n == 1 {
+			return []byte(`{"n":1}`), nil
+		}
+		done <- struct{}{}
+		return []byte(`{"n":2}`), nil
+	}
+
+	if _, err := c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+
+	c.now = func() time.Time { return now.Add(20 * time.Minute) }
+	body, err := c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch)
+	if err != nil {
+		t.Fatalf("stale fetch: %v", err)
+	}
+	if string(body) != `{"n":1}` {
+		t.Fatalf("expected stale fetch to return cached body immediately, got %s", body)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("background refresh never ran")
+	}
+
+	c.now = func() time.Time { return now.Add(20*time.Minute + time.Millisecond) }
+	body, err = c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch)
+	if err != nil {
+		t.Fatalf("post-refresh fetch: %v", err)
+	}
+	if string(body) != `{"n":2}` {
+		t.Fatalf("expected background refresh to have updated the cache, got %s", body)
+	}
+}
+
+func TestWeatherCacheExpiredFetchesSynchronously(t *testing.T) {
+	now := time.Now()
+	c := newWeatherCache(t.TempDir(), 10*time.Minute, time.Hour)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	fetch := func(ctx context.Context) ([]byte, error) {
+		calls++
+		return []byte(`{"n":1}`), nil
+	}
+
+	if _, err := c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+
+	c.now = func() time.Time { return now.Add(2 * time.Hour) }
+	body, err := c.fetch(context.Background(), "owm_metric_1.00_2.00", fetch)
+	if err != nil {
+		t.Fatalf("expired fetch: %v", err)
+	}
+	if string(body) != `{"n":1}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if calls != 2 {
+		t.Fatalf("expected expired entry to refetch synchronously, got %d calls", calls)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "net/http"
+
+// apiError is a typed error that carries the HTTP status code the handler
+// should respond with, and (for upstream failures) the provider's own error
+// code, so it can be surfaced in logs and responses without either side
+// needing to re-derive it.
+type apiError struct {
+	status int
+	cod    string
+	msg    string
+}
+
+func (err *apiError) Error() string {
+	return err.msg
+}
+
+func badRequestError(msg string) error {
+	return &apiError{status: http.StatusBadRequest, msg: msg}
+}
+
+func internalError(msg string) error {
+	return &apiError{status: http.StatusInternalServerError, msg: msg}
+}
+
+// upstreamTimeoutError reports that an upstream provider didn't respond
+// before our request context's deadline.
+func upstreamTimeoutError(msg string) error {
+	return &apiError{status: http.StatusGatewayTimeout, msg: msg}
+}
+
+// upstreamError reports that an upstream provider answered, but with a
+// failure. statusCode is the upstream's own HTTP status; cod is whatever
+// error code it reported alongside (e.g. OWM's "cod" field).
+func upstreamError(statusCode int, cod, msg string) error {
+	return &apiError{status: upstreamStatus(statusCode), cod: cod, msg: msg}
+}
+
+// upstreamStatus maps an upstream provider's HTTP status to the status we
+// report to our own clients: 429 is passed straight through since it's
+// actionable (back off and retry), everything else upstream-side is a 502
+// since it reflects a problem with the upstream, not with the caller's
+// request.
+func upstreamStatus(statusCode int) int {
+	if statusCode == http.StatusTooManyRequests {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func entry(dtTxt string, temp float32, condition string, pop float32) owmForecastEntry {
+	e := owmForecastEntry{DtTxt: dtTxt, Pop: pop}
+	e.Main.Temperature = temp
+	if condition != "" {
+		e.Weather = []struct {
+			Main string `json:"main"`
+		}{{Main: condition}}
+	}
+	return e
+}
+
+func TestSummarizeDaysDominantConditionTieBreak(t *testing.T) {
+	data := owmForecastMessage{List: []owmForecastEntry{
+		entry("2026-07-27 09:00:00", 20, "Rain", 0.5),
+		entry("2026-07-27 12:00:00", 22, "Clouds", 0.1),
+	}}
+
+	days := summarizeDays(data, 5)
+	if len(days) != 1 {
+		t.Fatalf("got %d days, want 1", len(days))
+	}
+	// Rain and Clouds are tied at one entry each; the tie-break picks the
+	// lexicographically smaller name.
+	if got, want := days[0].Condition, "Clouds"; got != want {
+		t.Fatalf("got condition %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeDaysTruncatesToDays(t *testing.T) {
+	data := owmForecastMessage{List: []owmForecastEntry{
+		entry("2026-07-27 09:00:00", 20, "Clear", 0),
+		entry("2026-07-28 09:00:00", 20, "Clear", 0),
+		entry("2026-07-29 09:00:00", 20, "Clear", 0),
+	}}
+
+	days := summarizeDays(data, 2)
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	if days[0].Date != "2026-07-27" || days[1].Date != "2026-07-28" {
+		t.Fatalf("got dates %q, %q; want 2026-07-27, 2026-07-28", days[0].Date, days[1].Date)
+	}
+}
+
+func TestSummarizeDaysDropsEntryMissingDateSeparator(t *testing.T) {
+	data := owmForecastMessage{List: []owmForecastEntry{
+		entry("not-a-timestamp", 99, "Thunderstorm", 1),
+		entry("2026-07-27 09:00:00", 20, "Clear", 0),
+	}}
+
+	days := summarizeDays(data, 5)
+	if len(days) != 1 {
+		t.Fatalf("got %d days, want 1 (malformed entry should be dropped)", len(days))
+	}
+	if days[0].Date != "2026-07-27" {
+		t.Fatalf("got date %q, want 2026-07-27", days[0].Date)
+	}
+	if days[0].Condition != "Clear" {
+		t.Fatalf("got condition %q; the malformed entry's Thunderstorm should not have been counted", days[0].Condition)
+	}
+}
+
+func TestSummarizeDaysAveragesChanceOfPrecipitation(t *testing.T) {
+	data := owmForecastMessage{List: []owmForecastEntry{
+		entry("2026-07-27 09:00:00", 20, "Clear", 0.2),
+		entry("2026-07-27 12:00:00", 20, "Clear", 0.6),
+	}}
+
+	days := summarizeDays(data, 5)
+	if len(days) != 1 {
+		t.Fatalf("got %d days, want 1", len(days))
+	}
+	const want = 0.4
+	const epsilon = 0.001
+	if got := days[0].ChanceOfPrecipitation; got < want-epsilon || got > want+epsilon {
+		t.Fatalf("got chance_of_precipitation %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const geocodeCacheTTL = 30 * 24 * time.Hour
+
+// resolveCoordinates determines the (lat, lon) a request is asking about,
+// from whichever of lat/lon, q (city/state/country) or zip (zip/country) was
+// given. Exactly one of the three must be present.
+func resolveCoordinates(ctx context.Context, query url.Values, cache *weatherCache, key string) (lat, lon float32, err error) {
+	present := 0
+	if query.Has("lat") || query.Has("lon") {
+		present++
+	}
+	if query.Has("q") {
+		present++
+	}
+	if query.Has("zip") {
+		present++
+	}
+
+	switch {
+	case present == 0:
+		return 0, 0, badRequestError("provide one of: lat/lon, q, or zip")
+	case present > 1:
+		return 0, 0, badRequestError("lat/lon, q, and zip are mutually exclusive")
+	}
+
+	if query.Has("q") {
+		return geocodeCity(ctx, cache, query.Get("q"), key)
+	}
+
+	if query.Has("zip") {
+		return geocodeZip(ctx, cache, query.Get("zip"), key)
+	}
+
+	if !query.Has("lat") {
+		return 0, 0, badRequestError("missing query parameter: lat")
+	}
+	if !query.Has("lon") {
+		return 0, 0, badRequestError("missing query parameter: lon")
+	}
+
+	latitude, err := strconv.ParseFloat(query.Get("lat"), 32)
+	if err != nil {
+		return 0, 0, badRequestError("invalid value: lat")
+	}
+
+	longitude, err := strconv.ParseFloat(query.Get("lon"), 32)
+	if err != nil {
+		return 0, 0, badRequestError("invalid value: lon")
+	}
+
+	return float32(latitude), float32(longitude), nil
+}
+
+type owmGeocodeLocation struct {
+	Lat float32 `json:"lat"`
+	Lon float32 `json:"lon"`
+}
+
+// geocodeCity resolves a "City,State,Country" query (OWM's direct
+// geocoding format, e.g. "London,GB") to coordinates via OWM's geocoding
+// API.
+func geocodeCity(ctx context.Context, cache *weatherCache, q, key string) (lat, lon float32, err error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		URL := fmt.Sprintf(
+			"https://api.openweathermap.org/geo/1.0/direct?q=%s&limit=1&appid=%s",
+			url.QueryEscape(q),
+			key)
+		var body []byte
+		err := observeUpstream("geocode_city", func() error {
+			var err error
+			body, err = fetchOWM(ctx, "geocode_city", URL)
+			return err
+		})
+		return body, err
+	}
+
+	body, err := cachedGeocodeFetch(ctx, cache, "geocode_city_"+q, fetch)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var results []owmGeocodeLocation
+	if err := json.Unmarshal(body, &results); err != nil {
+		slog.Error("unmarshall geocode response", "error", err)
+		return 0, 0, internalError("internal server error")
+	}
+	if len(results) == 0 {
+		return 0, 0, badRequestError(fmt.Sprintf("no location found for: %s", q))
+	}
+
+	return results[0].Lat, results[0].Lon, nil
+}
+
+// geocodeZip resolves a "zip,country" query (e.g. "94040,US") to
+// coordinates via OWM's zip-code geocoding API.
+func geocodeZip(ctx context.Context, cache *weatherCache, zip, key string) (lat, lon float32, err error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		URL := fmt.Sprintf(
+			"https://api.openweathermap.org/geo/1.0/zip?zip=%s&appid=%s",
+			url.QueryEscape(zip),
+			key)
+		var body []byte
+		err := observeUpstream("geocode_zip", func() error {
+			var err error
+			body, err = fetchOWM(ctx, "geocode_zip", URL)
+			return err
+		})
+		return body, err
+	}
+
+	body, err := cachedGeocodeFetch(ctx, cache, "geocode_zip_"+zip, fetch)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var result owmGeocodeLocation
+	if err := json.Unmarshal(body, &result); err != nil {
+		slog.Error("unmarshall geocode response", "error", err)
+		return 0, 0, internalError("internal server error")
+	}
+
+	return result.Lat, result.Lon, nil
+}
+
+// cachedGeocodeFetch is geocodeCity/geocodeZip's shared caching wrapper.
+// Geocoding results rarely change, so they're cached for geocodeCacheTTL
+// with no stale-while-revalidate window: a cache miss always fetches
+// synchronously. cache may be nil, in which case every call fetches fresh.
+func cachedGeocodeFetch(ctx context.Context, cache *weatherCache, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if cache == nil {
+		return fetch(ctx)
+	}
+
+	geocodeCache := &weatherCache{dir: cache.dir, ttl: geocodeCacheTTL, maxAge: geocodeCacheTTL, now: cache.now}
+	return geocodeCache.fetch(ctx, key, fetch)
+}
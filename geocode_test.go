@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestResolveCoordinatesMutualExclusivity(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "lat/lon only", query: "lat=1&lon=2", wantErr: false},
+		{name: "missing both", query: "", wantErr: true},
+		{name: "lat/lon and q", query: "lat=1&lon=2&q=London,GB", wantErr: true},
+		{name: "q and zip", query: "q=London,GB&zip=94040,US", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse query: %v", err)
+			}
+
+			_, _, err = resolveCoordinates(context.Background(), query, nil, "test-key")
+			if tt.wantErr && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveCoordinatesInvalidLatLon(t *testing.T) {
+	query, _ := url.ParseQuery("lat=not-a-number&lon=2")
+	_, _, err := resolveCoordinates(context.Background(), query, nil, "test-key")
+	if err == nil {
+		t.Fatal("expected an error for invalid lat")
+	}
+}
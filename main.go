@@ -1,99 +1,201 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// upstreamTimeout bounds how long we wait on an upstream provider before
+// giving up, so a slow OWM/Open-Meteo response can't hang a handler.
+const upstreamTimeout = 10 * time.Second
+
 type owmErrorMessage struct {
 	Code    json.Number `json:"cod"`
 	Message string      `json:"message"`
 }
 
-type owmSuccessMessage struct {
-	Weather []struct {
-		Main string `json:"main"`
-	}
-	Main struct {
+type owmForecastEntry struct {
+	DtTxt string `json:"dt_txt"`
+	Main  struct {
 		Temperature float32 `json:"temp"`
 	} `json:"main"`
+	Weather []struct {
+		Main string `json:"main"`
+	} `json:"weather"`
+	Pop float32 `json:"pop"`
+}
+
+type owmForecastMessage struct {
+	List []owmForecastEntry `json:"list"`
 }
 
 type httpError struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
 }
 
-func (err *httpError) Error() string {
-	return err.Message
+type temperatureValue struct {
+	Value float32 `json:"value"`
+	Unit  string  `json:"unit"`
 }
 
 type response struct {
-	Status          string `json:"status"`
-	Condition       string `json:"condition"`
-	TemperatureFeel string `json:"temperature_feel"`
+	Status          string           `json:"status"`
+	Units           string           `json:"units"`
+	Temperature     temperatureValue `json:"temperature"`
+	FeelsLike       temperatureValue `json:"feels_like"`
+	Humidity        float32          `json:"humidity"`
+	Pressure        float32          `json:"pressure"`
+	WindSpeed       float32          `json:"wind_speed"`
+	WindDirection   float32          `json:"wind_direction"`
+	CloudCover      float32          `json:"cloud_cover"`
+	Condition       string           `json:"condition"`
+	Description     string           `json:"description"`
+	TemperatureFeel string           `json:"temperature_feel"`
+}
+
+type daySummary struct {
+	Date                  string  `json:"date"`
+	MinTemperatureFeel    string  `json:"min_temperature_feel"`
+	MaxTemperatureFeel    string  `json:"max_temperature_feel"`
+	Condition             string  `json:"condition"`
+	ChanceOfPrecipitation float32 `json:"chance_of_precipitation"`
+}
+
+type forecastResponse struct {
+	Status string       `json:"status"`
+	Days   []daySummary `json:"days"`
 }
 
 func main() {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
 	KEY := os.Getenv("OWM_KEY")
 	if KEY == "" {
 		panic("OWM_KEY is not set")
 	}
 
+	defaultProvider := os.Getenv("WEATHER_PROVIDER")
+	if defaultProvider == "" {
+		defaultProvider = os.Getenv("OWM_PROVIDER")
+	}
+
+	var cache *weatherCache
+	if dir := os.Getenv("WEATHER_CACHE_LOCATION"); dir != "" {
+		cache = newWeatherCache(dir, 10*time.Minute, time.Hour)
+	}
+
 	mux := http.NewServeMux()
 
+	mux.Handle("GET /metrics", metricsHandler())
+
 	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		requestID := requestIDFromContext(r.Context())
 		query := r.URL.Query()
 
-		lat, ok := query["lat"]
-		if !ok {
-			e(w, "missing query parameter: lat")
+		ctx, cancel := context.WithTimeout(r.Context(), upstreamTimeout)
+		defer cancel()
+
+		latitude, longitude, err := resolveCoordinates(ctx, query, cache, KEY)
+		if err != nil {
+			e(w, requestID, err)
 			return
 		}
 
-		lon, ok := query["lon"]
-		if !ok {
-			e(w, "missing query parameter: lon")
-			return
+		providerName := defaultProvider
+		if raw, ok := query["provider"]; ok {
+			providerName = raw[0]
 		}
 
-		latitude, err := strconv.ParseFloat(lat[0], 32)
+		units, err := parseUnits(query.Get("units"))
 		if err != nil {
-			e(w, "invalid value: lat")
+			e(w, requestID, badRequestError(err.Error()))
 			return
 		}
 
-		longitude, err := strconv.ParseFloat(lon[0], 32)
+		provider, err := newProvider(providerName, KEY, cache)
 		if err != nil {
-			e(w, "invalid value: lon")
+			e(w, requestID, badRequestError(err.Error()))
 			return
 		}
 
-		data, err := getWeather(float32(longitude), float32(latitude), KEY)
+		obs, err := provider.Current(ctx, latitude, longitude, units)
 		if err != nil {
-			e(w, err.Error())
+			e(w, requestID, err)
 			return
 		}
 
+		unit := temperatureUnitSymbol(units)
 		respData, err := json.Marshal(response{
-			Condition:       data.Weather[0].Main,
-			TemperatureFeel: getTemperature(data.Main.Temperature),
+			Units:           units,
+			Temperature:     temperatureValue{Value: obs.Temperature, Unit: unit},
+			FeelsLike:       temperatureValue{Value: obs.FeelsLike, Unit: unit},
+			Humidity:        obs.Humidity,
+			Pressure:        obs.Pressure,
+			WindSpeed:       obs.WindSpeed,
+			WindDirection:   obs.WindDirection,
+			CloudCover:      obs.CloudCover,
+			Condition:       obs.Condition,
+			Description:     obs.ConditionDescription,
+			TemperatureFeel: getTemperature(toCelsius(obs.FeelsLike, units)),
+		})
+		if err != nil {
+			e(w, requestID, internalError(err.Error()))
+			return
+		}
+
+		w.Write(respData)
+	})
+
+	mux.HandleFunc("GET /forecast", func(w http.ResponseWriter, r *http.Request) {
+		requestID := requestIDFromContext(r.Context())
+		query := r.URL.Query()
+
+		ctx, cancel := context.WithTimeout(r.Context(), upstreamTimeout)
+		defer cancel()
+
+		latitude, longitude, err := resolveCoordinates(ctx, query, cache, KEY)
+		if err != nil {
+			e(w, requestID, err)
+			return
+		}
+
+		days := 5
+		if raw, ok := query["days"]; ok {
+			days, err = strconv.Atoi(raw[0])
+			if err != nil || days < 1 || days > 5 {
+				e(w, requestID, badRequestError("invalid value: days"))
+				return
+			}
+		}
+
+		data, err := getForecast(ctx, cache, longitude, latitude, KEY)
+		if err != nil {
+			e(w, requestID, err)
+			return
+		}
+
+		respData, err := json.Marshal(forecastResponse{
+			Days: summarizeDays(data, days),
 		})
 		if err != nil {
-			e(w, err.Error())
+			e(w, requestID, internalError(err.Error()))
 			return
 		}
 
 		w.Write(respData)
 	})
 
-	http.ListenAndServe(":8081", mux)
+	http.ListenAndServe(":8081", withMetricsAndAccessLog(mux))
 }
 
 func getTemperature(x float32) string {
@@ -121,54 +223,141 @@ func bw(x, a, b float32) bool {
 	return x >= a && x < b
 }
 
-func getWeather(longitude, latitude float32, key string) (owmSuccessMessage, error) {
-	URL := fmt.Sprintf(
-		"https://api.openweathermap.org/data/2.5/weather?units=metric&lat=%f&lon=%f&appid=%s",
-		latitude,
-		longitude,
-		key)
+// getForecast fetches the 5-day/3-hour forecast for a coordinate, caching
+// the raw response like every other upstream fetch so dashboards polling
+// /forecast on an interval don't burn OWM quota on every poll. Unlike /,
+// forecast is intentionally OWM-only and ignores ?provider=/
+// WEATHER_PROVIDER: Open-Meteo support would need its own forecast
+// parsing/summarization, which isn't implemented.
+func getForecast(ctx context.Context, cache *weatherCache, longitude, latitude float32, key string) (owmForecastMessage, error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		URL := fmt.Sprintf(
+			"https://api.openweathermap.org/data/2.5/forecast?units=metric&lat=%f&lon=%f&appid=%s",
+			latitude,
+			longitude,
+			key)
 
-	resp, err := http.Get(URL)
-	if err != nil {
-		log.Println(fmt.Errorf("get weather: %w", err))
-		return owmSuccessMessage{}, errors.New("internal server error")
+		var body []byte
+		err := observeUpstream("owm_forecast", func() error {
+			var err error
+			body, err = fetchOWM(ctx, "owm_forecast", URL)
+			return err
+		})
+		return body, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	var body []byte
+	var err error
+	if cache != nil {
+		body, err = cache.fetch(ctx, coordCacheKey("owm_forecast", "metric", latitude, longitude), fetch)
+	} else {
+		body, err = fetch(ctx)
+	}
 	if err != nil {
-		log.Println(fmt.Errorf("read request body: %w", err))
-		return owmSuccessMessage{}, errors.New("internal server error")
+		return owmForecastMessage{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		var oError owmErrorMessage
-		err = json.Unmarshal(body, &oError)
-		if err != nil {
-			log.Println(fmt.Errorf("unmarshall error request body: %w", err))
-			return owmSuccessMessage{}, errors.New("internal server error")
+	var data owmForecastMessage
+	if err := json.Unmarshal(body, &data); err != nil {
+		slog.Error("unmarshall forecast body", "error", err)
+		return owmForecastMessage{}, internalError("internal server error")
+	}
+
+	return data, nil
+}
+
+// summarizeDays buckets the 3-hour forecast entries OWM returns into at most
+// `days` daily summaries, in chronological order.
+func summarizeDays(data owmForecastMessage, days int) []daySummary {
+	type accumulator struct {
+		minTemp    float32
+		maxTemp    float32
+		conditions map[string]int
+		popSum     float32
+		popCount   int
+	}
+
+	order := []string{}
+	byDate := map[string]*accumulator{}
+
+	for _, entry := range data.List {
+		date, _, found := strings.Cut(entry.DtTxt, " ")
+		if !found {
+			continue
 		}
 
-		log.Println(fmt.Errorf("non-200 response: %v %s", oError.Code, oError.Message))
-		return owmSuccessMessage{}, errors.New(oError.Message)
+		acc, ok := byDate[date]
+		if !ok {
+			if len(order) >= days {
+				continue
+			}
+			acc = &accumulator{
+				minTemp:    entry.Main.Temperature,
+				maxTemp:    entry.Main.Temperature,
+				conditions: map[string]int{},
+			}
+			byDate[date] = acc
+			order = append(order, date)
+		}
+
+		if entry.Main.Temperature < acc.minTemp {
+			acc.minTemp = entry.Main.Temperature
+		}
+		if entry.Main.Temperature > acc.maxTemp {
+			acc.maxTemp = entry.Main.Temperature
+		}
+		if len(entry.Weather) > 0 {
+			acc.conditions[entry.Weather[0].Main]++
+		}
+		acc.popSum += entry.Pop
+		acc.popCount++
 	}
 
-	var data owmSuccessMessage
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		log.Println(fmt.Errorf("unmarshall request body: %w", err))
-		return owmSuccessMessage{}, errors.New("internal server error")
+	summaries := make([]daySummary, 0, len(order))
+	for _, date := range order {
+		acc := byDate[date]
+
+		dominant := ""
+		dominantCount := -1
+		for condition, count := range acc.conditions {
+			if count > dominantCount || (count == dominantCount && condition < dominant) {
+				dominant = condition
+				dominantCount = count
+			}
+		}
+
+		var pop float32
+		if acc.popCount > 0 {
+			pop = acc.popSum / float32(acc.popCount)
+		}
+
+		summaries = append(summaries, daySummary{
+			Date:                  date,
+			MinTemperatureFeel:    getTemperature(acc.minTemp),
+			MaxTemperatureFeel:    getTemperature(acc.maxTemp),
+			Condition:             dominant,
+			ChanceOfPrecipitation: pop,
+		})
 	}
 
-	return data, nil
+	return summaries
 }
 
-func e(w http.ResponseWriter, msg string) {
-	hError, err := json.Marshal(httpError{Status: "error", Message: msg})
-	if err != nil {
-		panic(err)
+func e(w http.ResponseWriter, requestID string, err error) {
+	status := http.StatusInternalServerError
+	var aErr *apiError
+	if errors.As(err, &aErr) {
+		status = aErr.status
+	}
+
+	hError, mErr := json.Marshal(httpError{Status: "error", Message: err.Error(), RequestID: requestID})
+	if mErr != nil {
+		panic(mErr)
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	w.Write(hError)
-	log.Println(msg)
+
+	slog.Error("request failed", "request_id", requestID, "status", status, "error", err.Error())
 }
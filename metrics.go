@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_service_requests_total",
+		Help: "Total HTTP requests, by path and status code.",
+	}, []string{"path", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "weather_service_request_duration_seconds",
+		Help: "HTTP request latency, by path.",
+	}, []string{"path"})
+
+	requestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_service_requests_in_flight",
+		Help: "Number of HTTP requests currently being handled.",
+	})
+
+	upstreamLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "weather_service_upstream_latency_seconds",
+		Help: "Latency of upstream weather/geocoding provider calls.",
+	}, []string{"provider"})
+
+	upstreamErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_service_upstream_errors_total",
+		Help: "Total upstream provider errors, by provider and upstream error code.",
+	}, []string{"provider", "cod"})
+
+	cacheResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_service_cache_results_total",
+		Help: "Cache lookups, by result (hit, stale_hit, miss).",
+	}, []string{"result"})
+)
+
+// observeUpstream times a call to an upstream provider and records its
+// latency and, on failure, an error count keyed by the apiError's upstream
+// code (if any).
+func observeUpstream(provider string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	upstreamLatency.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		cod := ""
+		var aErr *apiError
+		if errors.As(err, &aErr) {
+			cod = aErr.cod
+		}
+		upstreamErrorsTotal.WithLabelValues(provider, cod).Inc()
+	}
+
+	return err
+}
+
+// statusRecorder captures the status code a handler wrote, so middleware can
+// report it after the handler has already written the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// withMetricsAndAccessLog wraps a handler with Prometheus request metrics
+// and a structured access log entry, so individual handlers don't need to
+// do either themselves.
+func withMetricsAndAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsInFlight.Inc()
+		defer requestsInFlight.Dec()
+
+		requestID := newRequestID()
+		r = r.WithContext(withRequestID(r.Context(), requestID))
+
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		start := time.Now()
+		next.ServeHTTP(rw, r)
+		duration := time.Since(start)
+
+		path := r.URL.Path
+
+		requestsTotal.WithLabelValues(path, strconv.Itoa(rw.status)).Inc()
+		requestDuration.WithLabelValues(path).Observe(duration.Seconds())
+
+		slog.Info("request",
+			"request_id", requestID,
+			"method", r.Method,
+			"path", path,
+			"status", rw.status,
+			"duration_ms", duration.Milliseconds(),
+			"lat", r.URL.Query().Get("lat"),
+			"lon", r.URL.Query().Get("lon"),
+		)
+	})
+}
+
+func metricsHandler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// openMeteoProvider is the Provider backed by Open-Meteo, which needs no API
+// key.
+type openMeteoProvider struct {
+	cache *weatherCache
+}
+
+type openMeteoMessage struct {
+	Current struct {
+		Temperature         float32 `json:"temperature_2m"`
+		ApparentTemperature float32 `json:"apparent_temperature"`
+		Humidity            float32 `json:"relative_humidity_2m"`
+		Pressure            float32 `json:"surface_pressure"`
+		WindSpeed           float32 `json:"wind_speed_10m"`
+		WindDirection       float32 `json:"wind_direction_10m"`
+		CloudCover          float32 `json:"cloud_cover"`
+		WeatherCode         int     `json:"weather_code"`
+	} `json:"current"`
+	Daily struct {
+		Sunrise []string `json:"sunrise"`
+		Sunset  []string `json:"sunset"`
+	} `json:"daily"`
+}
+
+// weatherCodeCondition maps WMO weather interpretation codes, as used by
+// Open-Meteo, to the same coarse/detailed condition pair OWM exposes.
+func weatherCodeCondition(code int) (condition, description string) {
+	switch {
+	case code == 0:
+		return "Clear", "clear sky"
+	case code <= 3:
+		return "Clouds", "partly cloudy"
+	case code == 45 || code == 48:
+		return "Fog", "fog"
+	case code >= 51 && code <= 57:
+		return "Drizzle", "drizzle"
+	case code >= 61 && code <= 67:
+		return "Rain", "rain"
+	case code >= 71 && code <= 77:
+		return "Snow", "snow"
+	case code >= 80 && code <= 82:
+		return "Rain", "rain showers"
+	case code >= 85 && code <= 86:
+		return "Snow", "snow showers"
+	case code >= 95:
+		return "Thunderstorm", "thunderstorm"
+	default:
+		return "Unknown", "unknown"
+	}
+}
+
+func (p *openMeteoProvider) Current(ctx context.Context, lat, lon float32, units string) (Observation, error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		return p.fetchCurrent(ctx, lat, lon, units)
+	}
+
+	var body []byte
+	var err error
+	if p.cache != nil {
+		body, err = p.cache.fetch(ctx, coordCacheKey("open-meteo", units, lat, lon), fetch)
+	} else {
+		body, err = fetch(ctx)
+	}
+	if err != nil {
+		return Observation{}, err
+	}
+
+	var data openMeteoMessage
+	if err := json.Unmarshal(body, &data); err != nil {
+		slog.Error("unmarshall open-meteo response body", "error", err)
+		return Observation{}, internalError("internal server error")
+	}
+
+	condition, description := weatherCodeCondition(data.Current.WeatherCode)
+
+	temperature := data.Current.Temperature
+	feelsLike := data.Current.ApparentTemperature
+	if units == "standard" {
+		// Open-Meteo has no Kelvin option; it gave us Celsius.
+		temperature += 273.15
+		feelsLike += 273.15
+	}
+
+	obs := Observation{
+		Units:                units,
+		Temperature:          temperature,
+		FeelsLike:            feelsLike,
+		Humidity:             data.Current.Humidity,
+		Pressure:             data.Current.Pressure,
+		WindSpeed:            data.Current.WindSpeed,
+		WindDirection:        data.Current.WindDirection,
+		CloudCover:           data.Current.CloudCover,
+		Condition:            condition,
+		ConditionDescription: description,
+	}
+	if len(data.Daily.Sunrise) > 0 {
+		obs.Sunrise = parseLocalTime(data.Daily.Sunrise[0])
+	}
+	if len(data.Daily.Sunset) > 0 {
+		obs.Sunset = parseLocalTime(data.Daily.Sunset[0])
+	}
+
+	return obs, nil
+}
+
+// fetchCurrent fetches the raw current-weather response body from
+// Open-Meteo.
+func (p *openMeteoProvider) fetchCurrent(ctx context.Context, lat, lon float32, units string) ([]byte, error) {
+	temperatureUnit := "celsius"
+	windSpeedUnit := "ms"
+	if units == "imperial" {
+		temperatureUnit = "fahrenheit"
+		windSpeedUnit = "mph"
+	}
+
+	URL := fmt.Sprintf(
+		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current=temperature_2m,apparent_temperature,relative_humidity_2m,surface_pressure,wind_speed_10m,wind_direction_10m,cloud_cover,weather_code&daily=sunrise,sunset&timezone=auto&temperature_unit=%s&wind_speed_unit=%s",
+		lat,
+		lon,
+		temperatureUnit,
+		windSpeedUnit)
+
+	var body []byte
+	err := observeUpstream("open-meteo", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+		if err != nil {
+			return fmt.Errorf("build open-meteo request: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				slog.Error("get open-meteo weather", "error", err)
+				return upstreamTimeoutError("timed out waiting for open-meteo")
+			}
+			slog.Error("get open-meteo weather", "error", err)
+			return internalError("internal server error")
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			slog.Error("read open-meteo response body", "error", err)
+			return internalError("internal server error")
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			slog.Warn("non-200 open-meteo response", "upstream_status", resp.StatusCode, "body", string(respBody))
+			return upstreamError(resp.StatusCode, "", "upstream provider error")
+		}
+
+		body = respBody
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// parseLocalTime parses the timezone-less "2006-01-02T15:04" timestamps
+// Open-Meteo returns when timezone=auto is set. It returns 0 if the value
+// can't be parsed.
+func parseLocalTime(s string) int64 {
+	t, err := time.ParseInLocation("2006-01-02T15:04", s, time.Local)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// fetchOWM issues a GET request against an OpenWeatherMap endpoint and
+// returns its raw response body, translating network and non-200 errors
+// into the apiError types callers already surface to clients. It's shared
+// by owmProvider's current-weather fetch, the forecast fetch and
+// geocoding, since all three talk to OWM the same way. endpoint identifies
+// the caller in log lines (e.g. "owm", "owm_forecast", "geocode_city") so
+// failures stay distinguishable even though the fetch logic is shared.
+func fetchOWM(ctx context.Context, endpoint, URL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build owm request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			slog.Error("get owm response", "endpoint", endpoint, "error", err)
+			return nil, upstreamTimeoutError("timed out waiting for owm")
+		}
+		slog.Error("get owm response", "endpoint", endpoint, "error", err)
+		return nil, internalError("internal server error")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.Error("read owm response body", "endpoint", endpoint, "error", err)
+		return nil, internalError("internal server error")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oError owmErrorMessage
+		if err := json.Unmarshal(body, &oError); err != nil {
+			slog.Error("unmarshall owm error body", "endpoint", endpoint, "error", err)
+			return nil, internalError("internal server error")
+		}
+
+		slog.Warn("non-200 owm response", "endpoint", endpoint, "upstream_status", resp.StatusCode, "cod", oError.Code, "message", oError.Message)
+		return nil, upstreamError(resp.StatusCode, oError.Code.String(), oError.Message)
+	}
+
+	return body, nil
+}
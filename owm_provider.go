@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+const owmBaseURL = "https://api.openweathermap.org"
+
+// owmProvider is the Provider backed by OpenWeatherMap's current-weather
+// endpoint.
+type owmProvider struct {
+	key     string
+	cache   *weatherCache
+	baseURL string // overridden in tests; defaults to owmBaseURL
+}
+
+type owmCurrentMessage struct {
+	Weather []struct {
+		Main        string `json:"main"`
+		Description string `json:"description"`
+	} `json:"weather"`
+	Main struct {
+		Temperature float32 `json:"temp"`
+		FeelsLike   float32 `json:"feels_like"`
+		Humidity    float32 `json:"humidity"`
+		Pressure    float32 `json:"pressure"`
+	} `json:"main"`
+	Wind struct {
+		Speed     float32 `json:"speed"`
+		Direction float32 `json:"deg"`
+	} `json:"wind"`
+	Clouds struct {
+		All float32 `json:"all"`
+	} `json:"clouds"`
+	Sys struct {
+		Sunrise int64 `json:"sunrise"`
+		Sunset  int64 `json:"sunset"`
+	} `json:"sys"`
+}
+
+func (p *owmProvider) Current(ctx context.Context, lat, lon float32, units string) (Observation, error) {
+	fetch := func(ctx context.Context) ([]byte, error) {
+		var body []byte
+		err := observeUpstream("owm", func() error {
+			var err error
+			body, err = p.fetchCurrent(ctx, lat, lon, units)
+			return err
+		})
+		return body, err
+	}
+
+	var body []byte
+	var err error
+	if p.cache != nil {
+		body, err = p.cache.fetch(ctx, coordCacheKey("owm", units, lat, lon), fetch)
+	} else {
+		body, err = fetch(ctx)
+	}
+	if err != nil {
+		return Observation{}, err
+	}
+
+	var data owmCurrentMessage
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		slog.Error("unmarshall weather response body", "error", err)
+		return Observation{}, internalError("internal server error")
+	}
+
+	obs := Observation{
+		Units:         units,
+		Temperature:   data.Main.Temperature,
+		FeelsLike:     data.Main.FeelsLike,
+		Humidity:      data.Main.Humidity,
+		Pressure:      data.Main.Pressure,
+		WindSpeed:     data.Wind.Speed,
+		WindDirection: data.Wind.Direction,
+		CloudCover:    data.Clouds.All,
+		Sunrise:       data.Sys.Sunrise,
+		Sunset:        data.Sys.Sunset,
+	}
+	if len(data.Weather) > 0 {
+		obs.Condition = data.Weather[0].Main
+		obs.ConditionDescription = data.Weather[0].Description
+	}
+
+	return obs, nil
+}
+
+// fetchCurrent fetches the raw current-weather response body from OWM.
+func (p *owmProvider) fetchCurrent(ctx context.Context, lat, lon float32, units string) ([]byte, error) {
+	base := p.baseURL
+	if base == "" {
+		base = owmBaseURL
+	}
+
+	URL := fmt.Sprintf(
+		"%s/data/2.5/weather?units=%s&lat=%f&lon=%f&appid=%s",
+		base,
+		units,
+		lat,
+		lon,
+		p.key)
+
+	return fetchOWM(ctx, "owm", URL)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func fakeOWMServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOWMProviderMapsUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "unauthorized",
+			status:     http.StatusUnauthorized,
+			body:       `{"cod":401,"message":"Invalid API key"}`,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			body:       `{"cod":429,"message":"Too many requests"}`,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "upstream server error",
+			status:     http.StatusInternalServerError,
+			body:       `{"cod":500,"message":"Internal error"}`,
+			wantStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeOWMServer(t, tt.status, tt.body)
+			p := &owmProvider{key: "test-key", baseURL: srv.URL}
+
+			_, err := p.Current(context.Background(), 1, 2, "metric")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var aErr *apiError
+			if !errors.As(err, &aErr) {
+				t.Fatalf("expected *apiError, got %T: %v", err, err)
+			}
+			if aErr.status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", aErr.status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestOWMProviderSuccess(t *testing.T) {
+	srv := fakeOWMServer(t, http.StatusOK, `{
+		"weather": [{"main": "Clouds", "description": "scattered clouds"}],
+		"main": {"temp": 15.5, "feels_like": 14.2, "humidity": 70, "pressure": 1012},
+		"wind": {"speed": 3.1, "deg": 180},
+		"clouds": {"all": 40},
+		"sys": {"sunrise": 1000, "sunset": 2000}
+	}`)
+	p := &owmProvider{key: "test-key", baseURL: srv.URL}
+
+	obs, err := p.Current(context.Background(), 1, 2, "metric")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if obs.Condition != "Clouds" || obs.Temperature != 15.5 || obs.Humidity != 70 {
+		t.Fatalf("unexpected observation: %+v", obs)
+	}
+}
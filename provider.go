@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// Observation is a normalized current-conditions reading, independent of
+// which upstream weather provider produced it. Temperature, FeelsLike,
+// Pressure and WindSpeed are all in the unit system named by Units.
+type Observation struct {
+	Units                string
+	Temperature          float32
+	FeelsLike            float32
+	Humidity             float32
+	Pressure             float32
+	WindSpeed            float32
+	WindDirection        float32
+	CloudCover           float32
+	Condition            string
+	ConditionDescription string
+	Sunrise              int64
+	Sunset               int64
+}
+
+// Provider fetches the current weather for a coordinate from some upstream
+// weather API. units is one of "metric", "imperial" or "standard".
+type Provider interface {
+	Current(ctx context.Context, lat, lon float32, units string) (Observation, error)
+}
+
+const defaultProviderName = "owm"
+
+// newProvider builds the Provider named by name. An empty name selects the
+// default provider (OWM). key is passed through to providers that need an
+// API key and ignored by those that don't. cache may be nil, in which case
+// the provider fetches directly with no on-disk caching.
+func newProvider(name, key string, cache *weatherCache) (Provider, error) {
+	if name == "" {
+		name = defaultProviderName
+	}
+
+	switch name {
+	case "owm":
+		return &owmProvider{key: key, cache: cache}, nil
+	case "open-meteo":
+		return &openMeteoProvider{cache: cache}, nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider: %s", name)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newRequestID returns a short random identifier to correlate a request's
+// log line with the error (if any) returned to the client.
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+type contextKey int
+
+const requestIDContextKey contextKey = 0
+
+// withRequestID attaches a request ID to ctx, for handlers downstream of the
+// access-log middleware to retrieve via requestIDFromContext.
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// requestIDFromContext returns the request ID attached by withRequestID, or
+// "" if none was attached.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
@@ -0,0 +1,47 @@
+package main
+
+import "fmt"
+
+const defaultUnits = "metric"
+
+// parseUnits validates a units query parameter value, defaulting to metric
+// when raw is empty.
+func parseUnits(raw string) (string, error) {
+	if raw == "" {
+		return defaultUnits, nil
+	}
+
+	switch raw {
+	case "metric", "imperial", "standard":
+		return raw, nil
+	default:
+		return "", fmt.Errorf("invalid value: units")
+	}
+}
+
+// temperatureUnitSymbol returns the conventional single-letter unit symbol
+// for a units system's temperature.
+func temperatureUnitSymbol(units string) string {
+	switch units {
+	case "imperial":
+		return "F"
+	case "standard":
+		return "K"
+	default:
+		return "C"
+	}
+}
+
+// toCelsius converts a temperature from the given units system to Celsius,
+// so that unit-agnostic logic (like temperature-feel bucketing) can operate
+// on a single scale.
+func toCelsius(value float32, units string) float32 {
+	switch units {
+	case "imperial":
+		return (value - 32) * 5 / 9
+	case "standard":
+		return value - 273.15
+	default:
+		return value
+	}
+}
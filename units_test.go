@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty defaults to metric", raw: "", want: "metric"},
+		{name: "metric", raw: "metric", want: "metric"},
+		{name: "imperial", raw: "imperial", want: "imperial"},
+		{name: "standard", raw: "standard", want: "standard"},
+		{name: "invalid", raw: "kelvin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUnits(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemperatureUnitSymbol(t *testing.T) {
+	tests := []struct {
+		units string
+		want  string
+	}{
+		{units: "metric", want: "C"},
+		{units: "imperial", want: "F"},
+		{units: "standard", want: "K"},
+		{units: "", want: "C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			if got := temperatureUnitSymbol(tt.units); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToCelsius(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		units string
+		want  float32
+	}{
+		{name: "metric passthrough", value: 20, units: "metric", want: 20},
+		{name: "imperial freezing", value: 32, units: "imperial", want: 0},
+		{name: "imperial boiling", value: 212, units: "imperial", want: 100},
+		{name: "standard absolute zero", value: 0, units: "standard", want: -273.15},
+		{name: "standard room temp", value: 293.15, units: "standard", want: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toCelsius(tt.value, tt.units)
+			const epsilon = 0.01
+			if got < tt.want-epsilon || got > tt.want+epsilon {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}